@@ -0,0 +1,228 @@
+package ari
+
+import (
+	"encoding/json"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// BridgeEvent is a single Stasis event scoped to a bridge, as delivered by
+// the ARI WebSocket event feed. Type mirrors the Stasis "type" field (e.g.
+// "BridgeCreated", "ChannelEnteredBridge"); Raw carries the untouched
+// JSON payload so callers can unmarshal into the richer event struct for
+// the specific type they asked for.
+type BridgeEvent struct {
+	Type      string          `json:"type"`
+	BridgeId  string          `json:"bridge_id"`
+	ChannelId string          `json:"channel_id,omitempty"` // set for channel-scoped events, e.g. ChannelEnteredBridge
+	Raw       json.RawMessage `json:"-"`
+}
+
+// closableEventChan is a buffered BridgeEvent channel that can be sent on
+// and closed from different goroutines without racing: closeOnce holds
+// the same lock as send, so it always waits for a send already in
+// progress to finish (or give up) before it closes the channel. Without
+// this, a send racing a close can panic with "send on closed channel".
+type closableEventChan struct {
+	ch chan BridgeEvent
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newClosableEventChan(depth int) *closableEventChan {
+	return &closableEventChan{ch: make(chan BridgeEvent, depth)}
+}
+
+// send delivers evt, blocking while the channel is full until either it is
+// read or done fires; done may be nil to block unconditionally. It is a
+// no-op once closeOnce has run.
+func (c *closableEventChan) send(evt BridgeEvent, done <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if done == nil {
+		c.ch <- evt
+		return
+	}
+	select {
+	case c.ch <- evt:
+	case <-done:
+	}
+}
+
+func (c *closableEventChan) closeOnce() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.ch)
+	}
+}
+
+// bridgeSub is a single registered interest in a bridge's events.
+type bridgeSub struct {
+	bridgeId string
+	events   map[string]bool // empty/nil means "all events"
+	out      *closableEventChan
+	done     <-chan struct{} // closed when the subscriber's ctx is done
+}
+
+func (s *bridgeSub) wants(eventType string) bool {
+	if len(s.events) == 0 {
+		return true
+	}
+	return s.events[eventType]
+}
+
+// bridgeEventBacklog bounds how many delivered-but-unread events are
+// buffered per subscriber before that subscriber's bridgeQueue worker
+// blocks trying to deliver to it.
+const bridgeEventBacklog = 64
+
+// bridgeQueueDepth bounds how many events a bridge's queue will hold
+// before DispatchBridgeEvent's caller blocks.
+const bridgeQueueDepth = 64
+
+// bridgeRegistry holds, per bridge, both its live subscribers and the
+// relay queue that fans events out to them. Subscriber-list and queue
+// lifecycle are updated together under the same lock so a queue can never
+// be torn down (on the last unsubscribe) or left missing (for a brand new
+// subscribe) out from under a concurrent call on the same bridge id.
+var bridgeRegistry = struct {
+	sync.Mutex
+	subs   map[string][]*bridgeSub
+	queues map[string]*closableEventChan
+}{
+	subs:   make(map[string][]*bridgeSub),
+	queues: make(map[string]*closableEventChan),
+}
+
+// runBridgeQueue is bridgeId's dedicated fan-out worker: it delivers
+// queued events to that bridge's subscribers, in order, one at a time,
+// until its queue is closed. Each bridge gets its own queue and worker, so
+// a subscriber slow to drain its own channel holds up that bridge's other
+// subscribers first; once that bridge's queue is full as well,
+// DispatchBridgeEvent blocks its caller, which stalls other bridges fed by
+// the same caller too.
+func runBridgeQueue(bridgeId string, q *closableEventChan) {
+	for evt := range q.ch {
+		bridgeRegistry.Lock()
+		subs := append([]*bridgeSub(nil), bridgeRegistry.subs[bridgeId]...)
+		bridgeRegistry.Unlock()
+
+		for _, sub := range subs {
+			if sub.wants(evt.Type) {
+				sub.out.send(evt, sub.done)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel of BridgeEvent for this bridge, optionally
+// filtered to the given Stasis event type names (BridgeCreated,
+// BridgeDestroyed, BridgeMerged, BridgeVideoSourceChanged,
+// ChannelEnteredBridge, ChannelLeftBridge, PlaybackStarted, PlaybackFinished,
+// RecordingStarted, RecordingFinished, ...). Passing no events subscribes to
+// all events seen for this bridge. The returned channel is closed when ctx
+// is done; callers must drain it to avoid leaking the subscription.
+func (b *Bridge) Subscribe(ctx context.Context, events ...string) (<-chan BridgeEvent, error) {
+	wanted := make(map[string]bool, len(events))
+	for _, e := range events {
+		wanted[e] = true
+	}
+
+	sub := &bridgeSub{
+		bridgeId: b.Id,
+		events:   wanted,
+		out:      newClosableEventChan(bridgeEventBacklog),
+		done:     ctx.Done(),
+	}
+
+	bridgeRegistry.Lock()
+	bridgeRegistry.subs[b.Id] = append(bridgeRegistry.subs[b.Id], sub)
+	q, ok := bridgeRegistry.queues[b.Id]
+	if !ok {
+		q = newClosableEventChan(bridgeQueueDepth)
+		bridgeRegistry.queues[b.Id] = q
+		go runBridgeQueue(b.Id, q)
+	}
+	bridgeRegistry.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		unsubscribeBridge(sub)
+		sub.out.closeOnce()
+	}()
+
+	return sub.out.ch, nil
+}
+
+// WatchBridge is a convenience wrapper around Subscribe for callers that
+// only have a bridge id on hand (e.g. from ListBridges) rather than a live
+// Bridge value.
+func (c *Client) WatchBridge(ctx context.Context, bridgeId string, events ...string) (<-chan BridgeEvent, error) {
+	b := Bridge{Id: bridgeId}
+	return b.Subscribe(ctx, events...)
+}
+
+// DispatchBridgeEvent hands a raw Stasis event to the given bridge's
+// relay queue, to be fanned out to its subscribers in order. It is called
+// by RunEventStream as events arrive off the ARI WebSocket; it is
+// exported so alternate transports (tests, replayed recordings) can feed
+// events through the same path. channelId should be the channel the event
+// concerns (e.g. the channel that entered or left the bridge), or "" for
+// events that aren't scoped to a single channel. Bridges with no
+// subscriber are a no-op: there is nothing to apply backpressure for.
+//
+// DispatchBridgeEvent blocks once bridgeId's queue is full, applying
+// backpressure to whoever is calling it (e.g. RunEventStream's WebSocket
+// reader) instead of dropping the event. Each bridge has its own queue, so
+// a bridge whose subscriber is slow only blocks delivery for that bridge
+// up to bridgeEventBacklog+bridgeQueueDepth events of slack; a caller
+// sharing one reader across many bridges (as RunEventStream does) will
+// itself stall once that slack is exhausted, same as any single
+// connection multiplexing multiple slow consumers - this trades the
+// previous drop-on-overflow behavior for never losing an event silently,
+// not for perfect isolation between bridges.
+func DispatchBridgeEvent(bridgeId string, eventType string, channelId string, raw json.RawMessage) {
+	bridgeRegistry.Lock()
+	q, ok := bridgeRegistry.queues[bridgeId]
+	bridgeRegistry.Unlock()
+	if !ok {
+		return
+	}
+
+	q.send(BridgeEvent{Type: eventType, BridgeId: bridgeId, ChannelId: channelId, Raw: raw}, nil)
+}
+
+// unsubscribeBridge removes a subscription from the registry once its
+// context has been cancelled, tearing down the bridge's relay queue too
+// if that was its last subscriber. Both updates happen under the same
+// lock Subscribe uses, so a concurrent Subscribe for the same bridge id
+// can never observe (or recreate into) a half-torn-down queue.
+func unsubscribeBridge(sub *bridgeSub) {
+	bridgeRegistry.Lock()
+	subs := bridgeRegistry.subs[sub.bridgeId]
+	for i, s := range subs {
+		if s == sub {
+			bridgeRegistry.subs[sub.bridgeId] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	var q *closableEventChan
+	if len(bridgeRegistry.subs[sub.bridgeId]) == 0 {
+		delete(bridgeRegistry.subs, sub.bridgeId)
+		q = bridgeRegistry.queues[sub.bridgeId]
+		delete(bridgeRegistry.queues, sub.bridgeId)
+	}
+	bridgeRegistry.Unlock()
+
+	if q != nil {
+		q.closeOnce()
+	}
+}
@@ -0,0 +1,53 @@
+package ari
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestBridgeSubscribeFiltersByEventType(t *testing.T) {
+	b := Bridge{Id: "bridge1"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := b.Subscribe(ctx, "ChannelEnteredBridge")
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	DispatchBridgeEvent("bridge1", "ChannelLeftBridge", "chanA", json.RawMessage(`{}`))
+	DispatchBridgeEvent("bridge1", "ChannelEnteredBridge", "chanA", json.RawMessage(`{}`))
+
+	select {
+	case evt := <-ch:
+		if evt.Type != "ChannelEnteredBridge" {
+			t.Fatalf("expected ChannelEnteredBridge, got %s", evt.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for filtered event")
+	}
+}
+
+func TestBridgeSubscribeClosesOnCancel(t *testing.T) {
+	b := Bridge{Id: "bridge2"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := b.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected channel to be closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel close")
+	}
+}
@@ -1,6 +1,9 @@
 package ari
 
 import (
+	"encoding/json"
+	"strings"
+
 	"code.google.com/p/go-uuid/uuid"
 	"golang.org/x/net/context"
 )
@@ -24,22 +27,41 @@ type CreateBridgeRequest struct {
 	Name string `json:"name,omitempty"`
 }
 
-//Request structure to add a channel to a bridge. Only Channel is required.
-//Channel field allows for comma-separated-values to add multiple channels.
+//Request structure to add one or more channels to a bridge. Only ChannelId
+//is required. ChannelId is serialized as a comma-separated list, since
+//that's the form the addChannel endpoint accepts for bulk joins.
 type AddChannelRequest struct {
-	ChannelId string `json:"channel"`
-	Role      string `json:"role,omitempty"`
+	ChannelId []string `json:"channel"`
+	Role      string   `json:"role,omitempty"`
+}
+
+//MarshalJSON joins ChannelId with commas, matching the addChannel
+//endpoint's comma-separated-values convention for its "channel" field.
+func (r AddChannelRequest) MarshalJSON() ([]byte, error) {
+	type alias struct {
+		ChannelId string `json:"channel"`
+		Role      string `json:"role,omitempty"`
+	}
+	return json.Marshal(alias{
+		ChannelId: strings.Join(r.ChannelId, ","),
+		Role:      r.Role,
+	})
 }
 
 //List all active bridges in Asterisk
 //Equivalent to GET /bridges
 func (c *Client) ListBridges() ([]Bridge, error) {
+	return c.ListBridgesContext(context.Background())
+}
+
+//ListBridgesContext is the context-aware variant of ListBridges. It
+//retries according to c.RetryPolicy.
+func (c *Client) ListBridgesContext(ctx context.Context) ([]Bridge, error) {
 	var m []Bridge
-	err := c.AriGet("/bridges", &m)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriGetContext(ctx, "/bridges", &m)
+	})
+	return m, err
 }
 
 // NewBridge is a simple wrapper to create a new,
@@ -52,74 +74,133 @@ func (c *Client) NewBridge() (Bridge, error) {
 //Create a new bridge
 //Equivalent to POST /bridges
 func (c *Client) CreateBridge(req CreateBridgeRequest) (Bridge, error) {
+	return c.CreateBridgeContext(context.Background(), req)
+}
+
+//CreateBridgeContext is the context-aware variant of CreateBridge. It
+//retries according to c.RetryPolicy.
+func (c *Client) CreateBridgeContext(ctx context.Context, req CreateBridgeRequest) (Bridge, error) {
 	var m Bridge
 
 	//send request
-	err := c.AriPost("/bridges", &m, &req)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges", &m, &req)
+	})
+	return m, err
 }
 
 //Update a bridge or create a new one (upsert)
 //Equivalent to POST /bridges/{bridgeId}
 func (c *Client) UpsertBridge(bridgeId string, req CreateBridgeRequest) (Bridge, error) {
+	return c.UpsertBridgeContext(context.Background(), bridgeId, req)
+}
+
+//UpsertBridgeContext is the context-aware variant of UpsertBridge. It
+//retries according to c.RetryPolicy.
+func (c *Client) UpsertBridgeContext(ctx context.Context, bridgeId string, req CreateBridgeRequest) (Bridge, error) {
 	var m Bridge
 
 	//send request
-	err := c.AriPost("/bridges/"+bridgeId, &m, &req)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId, &m, &req)
+	})
+	return m, err
 }
 
 //Get bridge details
 //Equivalent to Get /bridges/{bridgeId}
 func (c *Client) GetBridge(bridgeId string) (Bridge, error) {
+	return c.GetBridgeContext(context.Background(), bridgeId)
+}
+
+//GetBridgeContext is the context-aware variant of GetBridge. It retries
+//according to c.RetryPolicy.
+func (c *Client) GetBridgeContext(ctx context.Context, bridgeId string) (Bridge, error) {
 	var m Bridge
-	err := c.AriGet("/bridges/"+bridgeId, &m)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriGetContext(ctx, "/bridges/"+bridgeId, &m)
+	})
+	return m, err
 }
 
 //Add a channel to a bridge
 //Equivalent to Post /bridges/{bridgeId}/addChannel
 func (c *Client) AddChannel(bridgeId string, req AddChannelRequest) error {
+	return c.AddChannelContext(context.Background(), bridgeId, req)
+}
+
+//AddChannelContext is the context-aware variant of AddChannel. It retries
+//according to c.RetryPolicy.
+func (c *Client) AddChannelContext(ctx context.Context, bridgeId string, req AddChannelRequest) error {
 	//No return, so no model to create
 
 	//send request, no model so pass nil
-	err := c.AriPost("/bridges/"+bridgeId+"/addChannel", nil, &req)
-	if err != nil {
-		return err
-	}
-	return nil
+	return withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/addChannel", nil, &req)
+	})
 }
 
-//Remove a specific channel from a bridge
+//Remove one or more channels from a bridge
 //Equivalent to Post /bridges/{bridgeId}/removeChannel
-func (c *Client) RemoveChannel(bridgeId string, channelId string) error {
-	//Request structure to remove a channel from a bridge. Channel is required.
+func (c *Client) RemoveChannel(bridgeId string, channelIds []string) error {
+	return c.RemoveChannelContext(context.Background(), bridgeId, channelIds)
+}
+
+//RemoveChannelContext is the context-aware variant of RemoveChannel
+func (c *Client) RemoveChannelContext(ctx context.Context, bridgeId string, channelIds []string) error {
+	//Request structure to remove one or more channels from a bridge.
+	//ChannelId is required and, like AddChannelRequest, is serialized as a
+	//comma-separated list.
 	type request struct {
 		ChannelId string `json:"channel"`
 	}
 
-	req := request{channelId}
+	req := request{strings.Join(channelIds, ",")}
 
 	//pass request
-	err := c.AriPost("/bridges/"+bridgeId+"/removeChannel", nil, &req)
-	if err != nil {
-		return err
-	}
-	return nil
+	return withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/removeChannel", nil, &req)
+	})
+}
+
+//Set the active video source in a bridge to the video from the specified channel
+//Equivalent to POST /bridges/{bridgeId}/videoSource/{channelId}
+func (c *Client) SetVideoSource(bridgeId string, channelId string) error {
+	return c.SetVideoSourceContext(context.Background(), bridgeId, channelId)
+}
+
+//SetVideoSourceContext is the context-aware variant of SetVideoSource. It
+//retries according to c.RetryPolicy.
+func (c *Client) SetVideoSourceContext(ctx context.Context, bridgeId string, channelId string) error {
+	return withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/videoSource/"+channelId, nil, nil)
+	})
+}
+
+//Clear an explicitly set video source on a bridge, returning to the
+//default election behavior (most recent talker)
+//Equivalent to DELETE /bridges/{bridgeId}/videoSource
+func (c *Client) ClearVideoSource(bridgeId string) error {
+	return c.ClearVideoSourceContext(context.Background(), bridgeId)
+}
+
+//ClearVideoSourceContext is the context-aware variant of ClearVideoSource.
+//It retries according to c.RetryPolicy.
+func (c *Client) ClearVideoSourceContext(ctx context.Context, bridgeId string) error {
+	return withRetry(ctx, c, func() error {
+		return c.AriDeleteContext(ctx, "/bridges/"+bridgeId+"/videoSource", nil, nil)
+	})
 }
 
 //Play music on hold to a bridge or change the MOH class that's playing
 //Equivalent to  Post /bridges/{bridgeId}/moh (music on hold)
 func (c *Client) PlayMusicOnHold(bridgeId string, mohClass string) error {
+	return c.PlayMusicOnHoldContext(context.Background(), bridgeId, mohClass)
+}
+
+//PlayMusicOnHoldContext is the context-aware variant of PlayMusicOnHold.
+//It retries according to c.RetryPolicy.
+func (c *Client) PlayMusicOnHoldContext(ctx context.Context, bridgeId string, mohClass string) error {
 
 	//Request structure for playing music on hold to a bridge. MohClass is _not_ required.
 	type request struct {
@@ -129,65 +210,92 @@ func (c *Client) PlayMusicOnHold(bridgeId string, mohClass string) error {
 	req := request{mohClass}
 
 	//send request
-	err := c.AriPost("/bridges/"+bridgeId+"/moh", nil, &req)
-	if err != nil {
-		return err
-	}
-	return nil
+	return withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/moh", nil, &req)
+	})
 }
 
 //Start playback of media on specified bridge
 //Equivalent to  Post /bridges/{bridgeId}/play
 func (c *Client) PlayToBridge(bridgeId string, req PlayMediaRequest) (Playback, error) {
+	return c.PlayToBridgeContext(context.Background(), bridgeId, req)
+}
+
+//PlayToBridgeContext is the context-aware variant of PlayToBridge. It
+//retries according to c.RetryPolicy.
+func (c *Client) PlayToBridgeContext(ctx context.Context, bridgeId string, req PlayMediaRequest) (Playback, error) {
 	var m Playback
 
 	//send request
-	err := c.AriPost("/bridges/"+bridgeId+"/play", &m, &req)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/play", &m, &req)
+	})
+	return m, err
 }
 
 //Start playback of specific media on specified bridge
 //Equivalent to  Post /bridges/{bridgeId}/play/{playbackId}
 func (c *Client) PlayToBridgeById(bridgeId string, playbackId string, req PlayMediaRequest) (Playback, error) {
+	return c.PlayToBridgeByIdContext(context.Background(), bridgeId, playbackId, req)
+}
+
+//PlayToBridgeByIdContext is the context-aware variant of
+//PlayToBridgeById. It retries according to c.RetryPolicy.
+func (c *Client) PlayToBridgeByIdContext(ctx context.Context, bridgeId string, playbackId string, req PlayMediaRequest) (Playback, error) {
 	var m Playback
 
-	err := c.AriPost("/bridges/"+bridgeId+"/play/"+playbackId, &m, &req)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/play/"+playbackId, &m, &req)
+	})
+	return m, err
 }
 
 //start a recording on specified bridge
 //Equivalent to  Post /bridges/{bridgeId}/record
 func (c *Client) RecordBridge(bridgeId string, req RecordRequest) (LiveRecording, error) {
+	return c.RecordBridgeContext(context.Background(), bridgeId, req)
+}
+
+//RecordBridgeContext is the context-aware variant of RecordBridge. It
+//retries according to c.RetryPolicy.
+func (c *Client) RecordBridgeContext(ctx context.Context, bridgeId string, req RecordRequest) (LiveRecording, error) {
 
 	var m LiveRecording
 
 	//send request
-	err := c.AriPost("/bridges/"+bridgeId+"/record", &m, &req)
-	if err != nil {
-		return m, err
-	}
-	return m, nil
+	err := withRetry(ctx, c, func() error {
+		return c.AriPostContext(ctx, "/bridges/"+bridgeId+"/record", &m, &req)
+	})
+	return m, err
 }
 
 //Shut down a bridge. If any channels are in this bridge, they will be removed and resume whatever they were doing beforehand.
 //This means that the channels themselves are not deleted.
 //Equivalent to DELETE /bridges/{bridgeId}
 func (c *Client) BridgeDelete(bridgeId string) error {
-	err := c.AriDelete("/bridges/"+bridgeId, nil, nil)
-	return err
+	return c.BridgeDeleteContext(context.Background(), bridgeId)
+}
+
+//BridgeDeleteContext is the context-aware variant of BridgeDelete. It
+//retries according to c.RetryPolicy.
+func (c *Client) BridgeDeleteContext(ctx context.Context, bridgeId string) error {
+	return withRetry(ctx, c, func() error {
+		return c.AriDeleteContext(ctx, "/bridges/"+bridgeId, nil, nil)
+	})
 }
 
 //Stop playing music on hold to a bridge. This will only stop music on hold being played via POST bridges/{bridgeId}/moh.
 //Equivalent to DELETE /bridges/{bridgeId}/moh
 func (c *Client) BridgeStopMoh(bridgeId string) error {
-	err := c.AriDelete("/bridges/"+bridgeId+"/moh", nil, nil)
-	return err
+	return c.BridgeStopMohContext(context.Background(), bridgeId)
+}
+
+//BridgeStopMohContext is the context-aware variant of BridgeStopMoh. It
+//retries according to c.RetryPolicy.
+func (c *Client) BridgeStopMohContext(ctx context.Context, bridgeId string) error {
+	return withRetry(ctx, c, func() error {
+		return c.AriDeleteContext(ctx, "/bridges/"+bridgeId+"/moh", nil, nil)
+	})
 }
 
 //
@@ -0,0 +1,133 @@
+package ari
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// Client is a connection to a single Asterisk instance's ARI interface.
+type Client struct {
+	Url      string // base URL of the ARI endpoint, e.g. http://localhost:8088/ari
+	Username string
+	Password string
+
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+}
+
+// ariError is returned for non-2xx ARI responses. It carries the status
+// code so callers (e.g. RetryPolicy.IsRetryable) can tell a permanent 4xx
+// failure like "bridge already exists" apart from a transient 5xx.
+type ariError struct {
+	method     string
+	path       string
+	statusCode int
+	body       []byte
+}
+
+func (e *ariError) Error() string {
+	return fmt.Sprintf("ari: %s %s: unexpected status %d: %s", e.method, e.path, e.statusCode, e.body)
+}
+
+// StatusCode implements statusCoder.
+func (e *ariError) StatusCode() int {
+	return e.statusCode
+}
+
+// client returns the *http.Client requests are made with: whatever was
+// configured via SetHTTPClient, or http.DefaultClient if none has been set.
+func (c *Client) client() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return http.DefaultClient
+}
+
+//Perform a GET request against the ARI endpoint at path, decoding the JSON
+//response into v (which may be nil if the caller doesn't need the body).
+func (c *Client) AriGet(path string, v interface{}) error {
+	return c.AriGetContext(context.Background(), path, v)
+}
+
+//AriGetContext is the context-aware variant of AriGet.
+func (c *Client) AriGetContext(ctx context.Context, path string, v interface{}) error {
+	return c.ariDoContext(ctx, "GET", path, v, nil)
+}
+
+//Perform a POST request against the ARI endpoint at path, encoding body as
+//the JSON request payload and decoding the JSON response into v. Either
+//may be nil.
+func (c *Client) AriPost(path string, v interface{}, body interface{}) error {
+	return c.AriPostContext(context.Background(), path, v, body)
+}
+
+//AriPostContext is the context-aware variant of AriPost.
+func (c *Client) AriPostContext(ctx context.Context, path string, v interface{}, body interface{}) error {
+	return c.ariDoContext(ctx, "POST", path, v, body)
+}
+
+//Perform a DELETE request against the ARI endpoint at path.
+func (c *Client) AriDelete(path string, v interface{}, body interface{}) error {
+	return c.AriDeleteContext(context.Background(), path, v, body)
+}
+
+//AriDeleteContext is the context-aware variant of AriDelete.
+func (c *Client) AriDeleteContext(ctx context.Context, path string, v interface{}, body interface{}) error {
+	return c.ariDoContext(ctx, "DELETE", path, v, body)
+}
+
+//ariDoContext performs a single ARI HTTP request. It bails out immediately
+//if ctx is already done, and otherwise relies on http.Request.WithContext
+//to cancel the in-flight request when ctx is cancelled or its deadline
+//expires.
+func (c *Client) ariDoContext(ctx context.Context, method string, path string, v interface{}, body interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var bodyReader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(b)
+	} else {
+		bodyReader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.Url+path, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return &ariError{method: method, path: path, statusCode: resp.StatusCode, body: respBody}
+	}
+
+	if v == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, v)
+}
@@ -0,0 +1,267 @@
+package ari
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/net/context"
+)
+
+// flapCacheKey identifies an event for flap-suppression purposes: the same
+// Stasis event type seen again for the same channel in quick succession.
+func flapCacheKey(evt BridgeEvent) string {
+	return evt.ChannelId + ":" + evt.Type
+}
+
+// ParticipantRole classifies how a channel admitted into a Conference is
+// treated: whether it can speak, whether it can be auto-unmuted as the
+// active speaker, and whether it counts toward quorum.
+type ParticipantRole string
+
+const (
+	RoleModerator   ParticipantRole = "moderator"
+	RoleParticipant ParticipantRole = "participant"
+	RoleListener    ParticipantRole = "listener"
+)
+
+// Participant is a single channel admitted into a Conference.
+type Participant struct {
+	ChannelId string
+	Role      ParticipantRole
+	Muted     bool
+}
+
+// conferenceFlapCacheSize bounds how many recent per-channel events are
+// remembered for flap suppression.
+const conferenceFlapCacheSize = 256
+
+// conferenceFlapWindow is how soon a repeat of the same event for the same
+// channel must arrive to be suppressed as a flap.
+const conferenceFlapWindow = 2 * time.Second
+
+// Conference is a high-level orchestrator layered on top of a single
+// Bridge. It admits channels by role, picks one non-listener participant
+// as the active speaker and marks it unmuted (other participants' Muted
+// flags are left as they are), falls back to music on hold when down to one non-listener
+// participant, starts recording once quorum (two or more non-listener
+// participants) is reached, and destroys its underlying bridge once
+// empty.
+//
+// Conference drives itself from the bridge's event stream (see
+// Bridge.Subscribe): ChannelEnteredBridge/ChannelLeftBridge keep
+// conf.participants in sync with the bridge's real membership, so a
+// channel that leaves by any path (hangup, a transfer, another app
+// calling Client.RemoveChannel directly) is still reflected, not just
+// channels dismissed through this Conference. Callers still drive normal
+// operation through Admit/Dismiss and eventually Close the conference;
+// the event stream is what keeps the policy correct when membership
+// changes out from under it.
+type Conference struct {
+	client *Client
+	bridge Bridge
+
+	mu            sync.Mutex
+	participants  map[string]*Participant
+	activeSpeaker string
+	recording     bool
+	lastErr       error // most recent error from a background reconcile action, e.g. an auto-Close
+
+	flapCache *lru.Cache
+	cancel    context.CancelFunc
+}
+
+// NewConference creates a Conference around the given bridge id. The
+// bridge must already exist (see Client.NewBridge/CreateBridge).
+func NewConference(ctx context.Context, c *Client, bridgeId string) (*Conference, error) {
+	b, err := c.GetBridgeContext(ctx, bridgeId)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := lru.New(conferenceFlapCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	events, err := b.Subscribe(watchCtx,
+		"ChannelEnteredBridge", "ChannelLeftBridge",
+		"PlaybackStarted", "PlaybackFinished",
+		"RecordingStarted", "RecordingFinished",
+	)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	conf := &Conference{
+		client:       c,
+		bridge:       b,
+		participants: make(map[string]*Participant),
+		flapCache:    cache,
+		cancel:       cancel,
+	}
+
+	go conf.run(events)
+
+	return conf, nil
+}
+
+// Admit adds a channel to the conference's bridge with the given role.
+func (conf *Conference) Admit(ctx context.Context, channelId string, role ParticipantRole) error {
+	err := conf.client.AddChannelContext(ctx, conf.bridge.Id, AddChannelRequest{
+		ChannelId: []string{channelId},
+		Role:      string(role),
+	})
+	if err != nil {
+		return err
+	}
+
+	conf.mu.Lock()
+	conf.participants[channelId] = &Participant{ChannelId: channelId, Role: role}
+	conf.mu.Unlock()
+
+	conf.reconcile()
+	return nil
+}
+
+// Dismiss removes a channel from the conference's bridge.
+func (conf *Conference) Dismiss(ctx context.Context, channelId string) error {
+	err := conf.client.RemoveChannelContext(ctx, conf.bridge.Id, []string{channelId})
+	if err != nil {
+		return err
+	}
+
+	conf.mu.Lock()
+	delete(conf.participants, channelId)
+	conf.mu.Unlock()
+
+	conf.reconcile()
+	return nil
+}
+
+// Close stops the conference's event subscription and tears down its
+// bridge.
+func (conf *Conference) Close(ctx context.Context) error {
+	conf.cancel()
+	return conf.client.BridgeDeleteContext(ctx, conf.bridge.Id)
+}
+
+// Err returns the error from the most recent background reconcile action
+// (currently only the auto-Close triggered when the bridge empties), or
+// nil if none has failed. reconcile itself has no return path to report
+// errors to, since it also runs off the event stream rather than a direct
+// caller.
+func (conf *Conference) Err() error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	return conf.lastErr
+}
+
+// run drains the bridge's event stream until it is closed (i.e. until
+// Close cancels the subscription).
+func (conf *Conference) run(events <-chan BridgeEvent) {
+	for evt := range events {
+		conf.handle(evt)
+	}
+}
+
+// handle applies a single bridge event to the conference's participant
+// state and then reconciles MOH/recording/teardown against the result.
+// ChannelEnteredBridge/ChannelLeftBridge update conf.participants directly
+// from the bridge's real membership, so a channel that leaves the bridge
+// by any path is reflected here even if it never went through Dismiss.
+// Rapid repeats of the same event for the same channel - as happen during
+// attended transfers - are suppressed via flapCache so a flapping channel
+// doesn't thrash the membership/quorum logic below. Events for two
+// different channels are never deduped against each other.
+func (conf *Conference) handle(evt BridgeEvent) {
+	key := flapCacheKey(evt)
+	now := time.Now()
+	if last, seen := conf.flapCache.Get(key); seen && now.Sub(last.(time.Time)) < conferenceFlapWindow {
+		return
+	}
+	conf.flapCache.Add(key, now)
+
+	if evt.ChannelId != "" {
+		conf.mu.Lock()
+		switch evt.Type {
+		case "ChannelEnteredBridge":
+			// Admit already recorded channels it added with their
+			// requested role; don't clobber that when the
+			// corresponding event arrives.
+			if _, ok := conf.participants[evt.ChannelId]; !ok {
+				conf.participants[evt.ChannelId] = &Participant{ChannelId: evt.ChannelId, Role: RoleParticipant}
+			}
+		case "ChannelLeftBridge":
+			delete(conf.participants, evt.ChannelId)
+		}
+		conf.mu.Unlock()
+	}
+
+	conf.reconcile()
+}
+
+// reconcile applies the conference's standing policy given current
+// participant state: auto-unmuting the active speaker, auto-recording on
+// quorum, MOH when alone, and self-destruction when empty. Quorum and MOH
+// are judged by non-listener ("speaking") participants, matching
+// ensureActiveSpeakerLocked's own filtering; self-destruction is judged by
+// total occupancy, since a room of listeners alone is still occupied.
+func (conf *Conference) reconcile() {
+	conf.mu.Lock()
+	total := len(conf.participants)
+	speakers := 0
+	for _, p := range conf.participants {
+		if p.Role != RoleListener {
+			speakers++
+		}
+	}
+	recording := conf.recording
+	conf.ensureActiveSpeakerLocked()
+	conf.mu.Unlock()
+
+	ctx := context.Background()
+
+	switch {
+	case total == 0:
+		if err := conf.Close(ctx); err != nil {
+			conf.mu.Lock()
+			conf.lastErr = err
+			conf.mu.Unlock()
+		}
+	case speakers == 1:
+		conf.client.PlayMusicOnHoldContext(ctx, conf.bridge.Id, "")
+	case speakers >= 2 && !recording:
+		req := RecordRequest{Name: "conference-" + conf.bridge.Id, Format: "wav"}
+		if _, err := conf.client.RecordBridgeContext(ctx, conf.bridge.Id, req); err == nil {
+			conf.mu.Lock()
+			conf.recording = true
+			conf.mu.Unlock()
+		}
+	}
+}
+
+// ensureActiveSpeakerLocked picks a non-listener participant as the
+// active speaker if none is set, or drops the active speaker if it has
+// left. Which one is picked follows map iteration order, so it is not
+// necessarily the earliest admitted. Enforcing the mute state on the underlying channel is left to
+// the caller's channel-level client, since Conference only tracks the
+// bridge; this keeps Participant.Muted accurate for callers driving mute
+// off of it. conf.mu must be held.
+func (conf *Conference) ensureActiveSpeakerLocked() {
+	if p, ok := conf.participants[conf.activeSpeaker]; ok && p.Role != RoleListener {
+		return
+	}
+	conf.activeSpeaker = ""
+
+	for id, p := range conf.participants {
+		if p.Role == RoleListener {
+			continue
+		}
+		conf.activeSpeaker = id
+		p.Muted = false
+		break
+	}
+}
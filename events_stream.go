@@ -0,0 +1,73 @@
+package ari
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+)
+
+// RunEventStream connects to the ARI WebSocket event feed for app and
+// dispatches every bridge-scoped event it sees to DispatchBridgeEvent (see
+// dispatchStasisEvent for which events are dropped), so that
+// Bridge.Subscribe/Client.WatchBridge receive live traffic instead of
+// requiring a caller to feed events in by hand. It blocks until the
+// connection drops or ctx is done, and is meant to be run in its own
+// goroutine for the life of the Client:
+//
+//	go client.RunEventStream(ctx, "my-stasis-app")
+func (c *Client) RunEventStream(ctx context.Context, app string) error {
+	wsURL := strings.Replace(c.Url, "https://", "wss://", 1)
+	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
+	wsURL = fmt.Sprintf("%s/events?app=%s&api_key=%s:%s", wsURL, app, c.Username, c.Password)
+
+	conn, err := websocket.Dial(wsURL, "", c.Url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var raw json.RawMessage
+		if err := websocket.JSON.Receive(conn, &raw); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		dispatchStasisEvent(raw)
+	}
+}
+
+// dispatchStasisEvent picks the bridge and channel ids out of a raw Stasis
+// event envelope and hands it to DispatchBridgeEvent. Events with no bridge
+// (e.g. StasisStart for a channel never added to a bridge) aren't bridge
+// events and are dropped here; Conference and friends only care about the
+// bridge-scoped ones.
+func dispatchStasisEvent(raw json.RawMessage) {
+	var envelope struct {
+		Type   string `json:"type"`
+		Bridge struct {
+			Id string `json:"id"`
+		} `json:"bridge"`
+		Channel struct {
+			Id string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Bridge.Id == "" {
+		return
+	}
+	DispatchBridgeEvent(envelope.Bridge.Id, envelope.Type, envelope.Channel.Id, raw)
+}
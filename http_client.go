@@ -0,0 +1,19 @@
+package ari
+
+import "net/http"
+
+// SetHTTPClient configures the *http.Client that AriGet/AriPost/AriDelete
+// use for c's requests - e.g. an instrumented RoundTripper for tracing, a
+// custom TLS config for mTLS to Asterisk behind a sidecar, or a transport
+// tuned for high-rate AddChannel/RemoveChannel workloads. Passing nil
+// reverts c to http.DefaultClient.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.httpClient = hc
+}
+
+// HTTPClient returns the *http.Client that c's requests are made with:
+// whatever was last passed to SetHTTPClient, or http.DefaultClient if
+// none has been set.
+func (c *Client) HTTPClient() *http.Client {
+	return c.client()
+}
@@ -0,0 +1,22 @@
+package ari
+
+//Request structure for starting playback of one or more media URIs on a
+//bridge or channel. Media is required; the rest are optional and are left
+//to Asterisk's defaults when omitted.
+type PlayMediaRequest struct {
+	Media      string `json:"media"`
+	Lang       string `json:"lang,omitempty"`
+	OffsetMs   int    `json:"offsetms,omitempty"`
+	SkipMs     int    `json:"skipms,omitempty"`
+	PlaybackId string `json:"playbackId,omitempty"`
+}
+
+//Playback describes the state of a single media playback operation
+//started on a bridge or channel.
+type Playback struct {
+	Id        string `json:"id"`         // Unique Id for this playback operation
+	MediaUri  string `json:"media_uri"`  // URI for the media currently being played back
+	TargetUri string `json:"target_uri"` // URI for the bridge or channel this playback is taking place on
+	Language  string `json:"language"`   // Language code for the media
+	State     string `json:"state"`      // Current state of the playback operation (queued, playing, done)
+}
@@ -0,0 +1,26 @@
+package ari
+
+//Request structure for starting a recording. Name and Format are required;
+//the rest are optional and are left to Asterisk's defaults when omitted.
+type RecordRequest struct {
+	Name            string `json:"name"`
+	Format          string `json:"format"`
+	MaxDurationSecs int    `json:"maxDurationSeconds,omitempty"`
+	MaxSilenceSecs  int    `json:"maxSilenceSeconds,omitempty"`
+	IfExists        string `json:"ifExists,omitempty"`    // fail, overwrite, append
+	Beep            bool   `json:"beep,omitempty"`        // play a beep when recording starts
+	TerminateOn     string `json:"terminateOn,omitempty"` // none, any, '*', '#'
+}
+
+//LiveRecording describes an in-progress or finished recording, as returned
+//by RecordBridge/RecordChannel and friends.
+type LiveRecording struct {
+	Name            string `json:"name"`             // Base name for the recording
+	Format          string `json:"format"`           // Recording format (wav, gsm, etc.)
+	Cause           string `json:"cause"`            // Why recording stopped, if it has
+	State           string `json:"state"`            // Current state of the recording (recording, paused, done)
+	TargetUri       string `json:"target_uri"`       // URI for the bridge or channel being recorded
+	Duration        int    `json:"duration"`         // Duration in seconds
+	TalkingDuration int    `json:"talking_duration"` // Duration of talking in seconds, if silence detection is enabled
+	SilenceDuration int    `json:"silence_duration"` // Duration of silence in seconds, if silence detection is enabled
+}
@@ -0,0 +1,118 @@
+package ari
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// BackoffStrategy computes how long to wait before the next retry attempt,
+// given the (1-indexed) attempt number that just failed.
+type BackoffStrategy func(attempt int) time.Duration
+
+// LinearBackoff waits attempt*base before each successive retry.
+func LinearBackoff(base time.Duration) BackoffStrategy {
+	return func(attempt int) time.Duration {
+		return time.Duration(attempt) * base
+	}
+}
+
+// ExponentialBackoff waits base*2^(attempt-1) before each successive retry,
+// plus a random amount up to jitter, so that a herd of clients retrying
+// against a recovering Asterisk instance don't all collide on the same
+// schedule.
+func ExponentialBackoff(base, jitter time.Duration) BackoffStrategy {
+	return func(attempt int) time.Duration {
+		d := base << uint(attempt-1)
+		if jitter > 0 {
+			d += time.Duration(rand.Int63n(int64(jitter)))
+		}
+		return d
+	}
+}
+
+// statusCoder is implemented by errors returned from AriGet/AriPost/AriDelete
+// that carry the HTTP status code of the failed request. Errors that don't
+// implement it (e.g. network errors where no response was ever received)
+// are treated as status code 0.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// RetryPolicy configures how the Context-aware Bridge calls retry on
+// transient failures. The zero value disables retries, so existing
+// callers see no behavior change until they opt in.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    BackoffStrategy
+
+	// IsRetryable decides whether a given failure should be retried. If
+	// nil, DefaultIsRetryable is used.
+	IsRetryable func(statusCode int, err error) bool
+}
+
+// DefaultIsRetryable retries network errors (statusCode == 0, err != nil)
+// and 5xx responses. It never retries 4xx responses, since a request like
+// "add a channel that's already in the bridge" will fail the same way no
+// matter how many times it's sent.
+func DefaultIsRetryable(statusCode int, err error) bool {
+	if err == nil {
+		return false
+	}
+	if statusCode == 0 {
+		return true
+	}
+	return statusCode >= 500
+}
+
+// SetRetryPolicy attaches a RetryPolicy to c. It replaces any policy
+// previously set on c.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// RetryPolicy returns the RetryPolicy currently attached to c, or the zero
+// value (no retries) if none has been set.
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.retryPolicy
+}
+
+// withRetry runs op according to c's RetryPolicy, respecting ctx's deadline
+// between attempts. op should perform a single AriGet/AriPost/AriDelete
+// call and return its error unchanged.
+func withRetry(ctx context.Context, c *Client, op func() error) error {
+	policy := c.RetryPolicy()
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = DefaultIsRetryable
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = op()
+		if err == nil {
+			return nil
+		}
+
+		statusCode := 0
+		if sc, ok := err.(statusCoder); ok {
+			statusCode = sc.StatusCode()
+		}
+
+		if attempt >= policy.MaxRetries || !isRetryable(statusCode, err) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if policy.Backoff == nil {
+			continue
+		}
+		select {
+		case <-time.After(policy.Backoff(attempt + 1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}